@@ -0,0 +1,165 @@
+// Package discover contains the logic that inspects candidate processes for
+// instrumentation and decides which instrumentation, if any, applies to each
+// of them.
+package discover
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"golang.org/x/sync/singleflight"
+
+	"go.opentelemetry.io/obi/pkg/components/exec"
+	"go.opentelemetry.io/obi/pkg/components/svc"
+	"go.opentelemetry.io/obi/pkg/internal/goexec"
+)
+
+// InstrumentedExecutable bundles the information the rest of the discovery
+// pipeline needs about an executable: whether (and how) it can be
+// instrumented and, for Go binaries, the offsets required to do so.
+type InstrumentedExecutable struct {
+	FileInfo *exec.FileInfo
+	Type     svc.InstrumentableType
+	Offsets  *goexec.Offsets
+
+	// negative marks a cached result that represents a failed or
+	// not-instrumentable parse, so cache hits can be reported separately
+	// from hits on a successfully typed executable.
+	negative bool
+}
+
+// typer inspects an exec.FileInfo and decides which kind of instrumentation,
+// if any, applies to it. Inspecting a binary (parsing its ELF and, for Go
+// binaries, its DWARF symbols) is expensive, so results are cached by inode.
+type typer struct {
+	log *slog.Logger
+
+	// instrumentableCache avoids re-parsing a binary we have already typed.
+	// It is keyed by inode, which is stable for the lifetime of a file on
+	// disk but can be reused once the file is removed, so entries are only
+	// ever trusted for the lifetime of this typer.
+	instrumentableCache *lru.Cache[uint64, InstrumentedExecutable]
+
+	// inspectOffsets is a field, rather than a plain method, so tests can
+	// stub it out without touching the real ELF/DWARF parsing code.
+	inspectOffsets func(execElf *exec.FileInfo) (*goexec.Offsets, bool, error)
+
+	// parseGroup deduplicates concurrent asInstrumentable calls for the
+	// same binary, so N processes starting up at once (e.g. a Deployment
+	// scaling up, or a supervisor restarting many workers) only trigger a
+	// single parse, with every caller sharing its result.
+	parseGroup singleflight.Group
+
+	// disk, when set, persists parse results across restarts keyed by ELF
+	// build ID. It is nil when no persistent cache has been configured.
+	disk *diskCache
+
+	// metrics is nil-safe: a typer without metrics configured (e.g. in
+	// tests) simply does not record any.
+	metrics *typerMetrics
+
+	// limiter bounds the number of ELF/DWARF parses that can run
+	// concurrently. Parsing large Go binaries is CPU and memory intensive,
+	// and without this limit a burst of new processes (e.g. a Deployment
+	// scaling up) can make Beyla itself become the noisy neighbour on the
+	// host.
+	limiter *elfParseLimiter
+}
+
+// cacheKey is the identifier instrumentableCache uses to deduplicate work for
+// a given binary.
+func cacheKey(execElf *exec.FileInfo) uint64 {
+	return execElf.Ino
+}
+
+// singleflightKey is the identifier parseGroup uses to deduplicate in-flight
+// parses. It falls back to the executable path when the inode is not
+// available (e.g. it could not be stat'd).
+func singleflightKey(execElf *exec.FileInfo) string {
+	if execElf.Ino != 0 {
+		return strconv.FormatUint(execElf.Ino, 10)
+	}
+	return execElf.CmdExePath
+}
+
+// asInstrumentable returns the instrumentation type and offsets information
+// for the given executable, parsing it if it hasn't been seen before.
+// Concurrent calls for the same binary are deduplicated: only one of them
+// actually parses the ELF, while the rest wait for and share its result.
+func (t *typer) asInstrumentable(execElf *exec.FileInfo) InstrumentedExecutable {
+	key := cacheKey(execElf)
+
+	if cached, ok := t.instrumentableCache.Get(key); ok {
+		t.metrics.observeCacheLookup(cached.negative)
+		return cached
+	}
+
+	result, _, _ := t.parseGroup.Do(singleflightKey(execElf), func() (interface{}, error) {
+		// Another caller may have populated the cache while we were
+		// waiting to be scheduled.
+		if cached, ok := t.instrumentableCache.Get(key); ok {
+			t.metrics.observeCacheLookup(cached.negative)
+			return cached, nil
+		}
+
+		var buildID string
+		if t.disk != nil {
+			id, err := elfBuildID(execElf.CmdExePath)
+			if err != nil {
+				t.log.Debug("could not compute build ID, skipping disk cache", "path", execElf.CmdExePath, "error", err)
+			} else {
+				buildID = id
+				if cached, ok := t.disk.lookup(buildID); ok {
+					// lookup only knows about Type/Offsets; every other
+					// return path stamps FileInfo onto the result, so do
+					// the same here.
+					cached.FileInfo = execElf
+					t.metrics.observeDiskCacheHit()
+					t.instrumentableCache.Add(key, cached)
+					return cached, nil
+				}
+			}
+		}
+
+		t.limiter.acquire()
+		t.metrics.parseStarted()
+		parseStart := time.Now()
+		offsets, ok, err := t.inspectOffsets(execElf)
+		parseDuration := time.Since(parseStart)
+		t.metrics.parseFinished()
+		t.limiter.release(parseDuration < elfParseFastThreshold)
+
+		instrumented := InstrumentedExecutable{FileInfo: execElf}
+		if err == nil && ok {
+			instrumented.Type = svc.InstrumentableGolang
+			instrumented.Offsets = offsets
+		} else {
+			if err != nil {
+				t.log.Debug("error inspecting offsets", "path", execElf.CmdExePath, "error", err)
+				t.metrics.observeParseError(classifyParseError(err))
+			}
+			instrumented.Type = svc.InstrumentableGeneric
+			instrumented.negative = true
+		}
+		t.metrics.observeParseDuration(instrumented.Type, parseDuration)
+
+		// Negative results are cached too: a binary we failed to
+		// instrument once won't start succeeding on the next call, and
+		// caching the failure avoids a thundering herd of repeated
+		// parses for it.
+		t.instrumentableCache.Add(key, instrumented)
+		// Only successful parses are persisted to disk: unlike the
+		// in-memory cache, the disk cache outlives this process, and a
+		// transient inspectOffsets failure written there would permanently
+		// suppress re-parsing of that binary across restarts.
+		if t.disk != nil && buildID != "" && !instrumented.negative {
+			t.disk.store(buildID, instrumented)
+		}
+
+		return instrumented, nil
+	})
+
+	return result.(InstrumentedExecutable)
+}
@@ -0,0 +1,32 @@
+package discover
+
+import "time"
+
+// DiscoveryConfig groups the user-facing configuration knobs for the
+// discovery subsystem's ELF-parsing pipeline.
+type DiscoveryConfig struct {
+	// ELFParseConcurrency caps the number of ELF/DWARF parses that may run
+	// at once. Zero (the default) selects a value derived from GOMAXPROCS,
+	// via defaultELFParseConcurrency.
+	ELFParseConcurrency int
+
+	// AdaptiveELFParseConcurrency, when true, lets the effective
+	// concurrency limit shrink under memory pressure (see
+	// ELFParseRSSCeilingBytes) and grow back towards ELFParseConcurrency
+	// once parses are completing quickly again.
+	AdaptiveELFParseConcurrency bool
+
+	// ELFParseRSSCeilingBytes is the resident set size above which adaptive
+	// concurrency starts shrinking the permit count. Ignored unless
+	// AdaptiveELFParseConcurrency is set.
+	ELFParseRSSCeilingBytes uint64
+
+	// DiskCacheDir, when non-empty, enables the persistent on-disk cache of
+	// parsed Go offsets, rooted at this directory.
+	DiskCacheDir string
+
+	// DiskCacheMaxAge and DiskCacheMaxFiles bound the size of the on-disk
+	// cache. Zero disables the corresponding check.
+	DiskCacheMaxAge   time.Duration
+	DiskCacheMaxFiles int
+}
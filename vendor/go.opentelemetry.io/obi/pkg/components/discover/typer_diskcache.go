@@ -0,0 +1,266 @@
+package discover
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"debug/elf"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/obi/pkg/components/svc"
+	"go.opentelemetry.io/obi/pkg/internal/goexec"
+)
+
+// diskCacheFormatVersion is bumped whenever the serialized shape of
+// diskCacheEntry (or the goexec.Offsets it embeds) changes, so entries
+// written by a previous, incompatible version of Beyla are ignored instead
+// of being misread.
+const diskCacheFormatVersion = 1
+
+// diskCacheEntry is the on-disk representation of a single cached parse
+// result.
+type diskCacheEntry struct {
+	Version int
+	Type    svc.InstrumentableType
+	Offsets *goexec.Offsets
+}
+
+// diskCacheWrite is a pending write, processed asynchronously by
+// diskCache.run so that asInstrumentable never blocks on writing to disk.
+// Lookups, and the build ID computation that precedes them, still read from
+// disk synchronously.
+type diskCacheWrite struct {
+	buildID string
+	entry   diskCacheEntry
+}
+
+// diskCache persists parsed Go offsets across Beyla restarts, keyed by the
+// ELF build ID of the instrumented binary. Writes are asynchronous and
+// best-effort: failing to persist an entry only costs a re-parse on the next
+// restart, never correctness.
+type diskCache struct {
+	dir      string
+	maxAge   time.Duration
+	maxFiles int
+	log      *slog.Logger
+
+	writes    chan diskCacheWrite
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// newDiskCache creates a disk cache rooted at dir, creating the directory if
+// necessary, and starts its background writer goroutine. A zero maxAge or
+// maxFiles disables the corresponding eviction check.
+func newDiskCache(dir string, maxAge time.Duration, maxFiles int, log *slog.Logger) (*diskCache, error) {
+	if log == nil {
+		log = slog.Default()
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating disk cache dir %q: %w", dir, err)
+	}
+
+	c := &diskCache{
+		dir:      dir,
+		maxAge:   maxAge,
+		maxFiles: maxFiles,
+		log:      log,
+		writes:   make(chan diskCacheWrite, 64),
+	}
+
+	c.wg.Add(1)
+	go c.run()
+
+	return c, nil
+}
+
+func (c *diskCache) path(buildID string) string {
+	return filepath.Join(c.dir, buildID+".cache")
+}
+
+// lookup returns the cached entry for buildID, if any. Entries written by an
+// incompatible cache format version are treated as a miss and removed.
+func (c *diskCache) lookup(buildID string) (InstrumentedExecutable, bool) {
+	data, err := os.ReadFile(c.path(buildID))
+	if err != nil {
+		return InstrumentedExecutable{}, false
+	}
+
+	var entry diskCacheEntry
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&entry); err != nil {
+		c.log.Debug("discarding corrupt disk cache entry", "buildID", buildID, "error", err)
+		_ = os.Remove(c.path(buildID))
+		return InstrumentedExecutable{}, false
+	}
+
+	if entry.Version != diskCacheFormatVersion {
+		c.log.Debug("discarding disk cache entry with incompatible version", "buildID", buildID, "version", entry.Version)
+		_ = os.Remove(c.path(buildID))
+		return InstrumentedExecutable{}, false
+	}
+
+	return InstrumentedExecutable{Type: entry.Type, Offsets: entry.Offsets}, true
+}
+
+// store asynchronously persists ie under buildID. The write is dropped
+// rather than blocking the caller if the writer goroutine is backed up.
+func (c *diskCache) store(buildID string, ie InstrumentedExecutable) {
+	entry := diskCacheEntry{Version: diskCacheFormatVersion, Type: ie.Type, Offsets: ie.Offsets}
+	select {
+	case c.writes <- diskCacheWrite{buildID: buildID, entry: entry}:
+	default:
+		c.log.Debug("disk cache writer backed up, dropping entry", "buildID", buildID)
+	}
+}
+
+// close stops the writer goroutine, waiting for any in-flight write to
+// finish. It is primarily useful in tests, and safe to call more than once.
+func (c *diskCache) close() {
+	c.closeOnce.Do(func() {
+		close(c.writes)
+	})
+	c.wg.Wait()
+}
+
+func (c *diskCache) run() {
+	defer c.wg.Done()
+	for w := range c.writes {
+		if err := c.writeEntry(w.buildID, w.entry); err != nil {
+			c.log.Debug("failed to persist disk cache entry", "buildID", w.buildID, "error", err)
+			continue
+		}
+		c.evict()
+	}
+}
+
+// writeEntry serializes entry and atomically renames it into place, so a
+// concurrent lookup never observes a partially written file.
+func (c *diskCache) writeEntry(buildID string, entry diskCacheEntry) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(entry); err != nil {
+		return err
+	}
+
+	tmp := fmt.Sprintf("%s.%d.tmp", c.path(buildID), os.Getpid())
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path(buildID))
+}
+
+// evict enforces the age- and count-based eviction policy. It runs after
+// every write, so the cache directory never grows without bound even if
+// Beyla is never restarted.
+func (c *diskCache) evict() {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+
+	type agedFile struct {
+		name    string
+		modTime time.Time
+	}
+	var files []agedFile
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".cache" {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if c.maxAge > 0 && time.Since(info.ModTime()) > c.maxAge {
+			_ = os.Remove(filepath.Join(c.dir, e.Name()))
+			continue
+		}
+		files = append(files, agedFile{name: e.Name(), modTime: info.ModTime()})
+	}
+
+	if c.maxFiles <= 0 || len(files) <= c.maxFiles {
+		return
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, f := range files[:len(files)-c.maxFiles] {
+		_ = os.Remove(filepath.Join(c.dir, f.name))
+	}
+}
+
+// elfBuildID returns an identifier stable across restarts for the binary at
+// path: the ELF NT_GNU_BUILD_ID note when present, or a SHA256 digest of the
+// file contents as a fallback for binaries built without one.
+func elfBuildID(path string) (string, error) {
+	if id, ok := readGNUBuildID(path); ok {
+		return "gnu-" + id, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading %q for build ID fallback: %w", path, err)
+	}
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("sha256-%x", sum), nil
+}
+
+func readGNUBuildID(path string) (string, bool) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	sec := f.Section(".note.gnu.build-id")
+	if sec == nil {
+		return "", false
+	}
+
+	data, err := sec.Data()
+	if err != nil {
+		return "", false
+	}
+
+	return parseGNUBuildIDNote(data)
+}
+
+// parseGNUBuildIDNote parses an ELF note section, as described in the ELF(5)
+// manual, looking for an NT_GNU_BUILD_ID (type 3) note and returning its
+// payload hex-encoded.
+func parseGNUBuildIDNote(data []byte) (string, bool) {
+	const noteHeaderSize = 12
+	const ntGNUBuildID = 3
+
+	for len(data) >= noteHeaderSize {
+		nameSize := binary.LittleEndian.Uint32(data[0:4])
+		descSize := binary.LittleEndian.Uint32(data[4:8])
+		noteType := binary.LittleEndian.Uint32(data[8:12])
+
+		descStart := noteHeaderSize + int(align4(nameSize))
+		descEnd := descStart + int(descSize)
+		if descStart < 0 || descEnd > len(data) {
+			return "", false
+		}
+
+		if noteType == ntGNUBuildID {
+			return fmt.Sprintf("%x", data[descStart:descEnd]), true
+		}
+
+		next := noteHeaderSize + int(align4(nameSize)) + int(align4(descSize))
+		if next <= 0 || next > len(data) {
+			break
+		}
+		data = data[next:]
+	}
+	return "", false
+}
+
+func align4(n uint32) uint32 {
+	return (n + 3) &^ 3
+}
@@ -0,0 +1,108 @@
+package discover
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/obi/pkg/components/svc"
+	"go.opentelemetry.io/obi/pkg/internal/goexec"
+)
+
+func TestDiskCacheStoreAndLookup(t *testing.T) {
+	dir := t.TempDir()
+	c, err := newDiskCache(dir, 0, 0, slog.Default())
+	require.NoError(t, err)
+	defer c.close()
+
+	want := InstrumentedExecutable{Type: svc.InstrumentableGolang, Offsets: &goexec.Offsets{}}
+	c.store("build-123", want)
+	c.close()
+
+	got, ok := c.lookup("build-123")
+	assert.True(t, ok)
+	assert.Equal(t, want.Type, got.Type)
+}
+
+func TestDiskCacheMissingEntry(t *testing.T) {
+	dir := t.TempDir()
+	c, err := newDiskCache(dir, 0, 0, slog.Default())
+	require.NoError(t, err)
+	defer c.close()
+
+	_, ok := c.lookup("does-not-exist")
+	assert.False(t, ok)
+}
+
+func TestDiskCacheDiscardsCorruptEntry(t *testing.T) {
+	dir := t.TempDir()
+	c, err := newDiskCache(dir, 0, 0, slog.Default())
+	require.NoError(t, err)
+	defer c.close()
+
+	require.NoError(t, os.WriteFile(c.path("corrupt"), []byte("not a valid gob stream"), 0o644))
+
+	_, ok := c.lookup("corrupt")
+	assert.False(t, ok, "corrupt entries must be treated as a cache miss")
+	_, statErr := os.Stat(c.path("corrupt"))
+	assert.True(t, os.IsNotExist(statErr), "corrupt entry should be removed from disk")
+}
+
+func TestDiskCacheDiscardsVersionMismatch(t *testing.T) {
+	dir := t.TempDir()
+	c, err := newDiskCache(dir, 0, 0, slog.Default())
+	require.NoError(t, err)
+	defer c.close()
+
+	require.NoError(t, c.writeEntry("stale", diskCacheEntry{Version: diskCacheFormatVersion + 1, Type: svc.InstrumentableGolang}))
+
+	_, ok := c.lookup("stale")
+	assert.False(t, ok, "entries from an incompatible format version must be treated as a cache miss")
+}
+
+func TestDiskCacheConcurrentWritersToSameKey(t *testing.T) {
+	dir := t.TempDir()
+	c, err := newDiskCache(dir, 0, 0, slog.Default())
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.store("shared-key", InstrumentedExecutable{Type: svc.InstrumentableGolang, Offsets: &goexec.Offsets{}})
+		}()
+	}
+	wg.Wait()
+	c.close()
+
+	got, ok := c.lookup("shared-key")
+	assert.True(t, ok)
+	assert.Equal(t, svc.InstrumentableGolang, got.Type)
+}
+
+func TestDiskCacheEvictsByCount(t *testing.T) {
+	dir := t.TempDir()
+	c, err := newDiskCache(dir, 0, 2, slog.Default())
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		c.store(string(rune('a'+i)), InstrumentedExecutable{Type: svc.InstrumentableGolang, Offsets: &goexec.Offsets{}})
+	}
+	c.close()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var remaining int
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) == ".cache" {
+			remaining++
+		}
+	}
+	assert.LessOrEqual(t, remaining, 2, "cache directory should be trimmed down to maxFiles entries")
+}
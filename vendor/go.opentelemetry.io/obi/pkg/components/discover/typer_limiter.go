@@ -0,0 +1,149 @@
+package discover
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// elfParseFastThreshold is the inspectOffsets duration below which, in
+// adaptive mode, the limiter considers a parse "fast" and grows its permit
+// count back towards the configured maximum.
+const elfParseFastThreshold = 200 * time.Millisecond
+
+// elfParseLimiter bounds the number of ELF/DWARF parses that can run
+// concurrently. A nil *elfParseLimiter is valid and imposes no limit at all,
+// which keeps it safe to use in tests that construct a typer without going
+// through newTyper.
+//
+// In adaptive mode, the effective permit count shrinks when process RSS
+// exceeds rssCeiling and grows back, one permit at a time, whenever a parse
+// completes faster than elfParseFastThreshold - a proxy for "the host has
+// headroom".
+type elfParseLimiter struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	inFlight int
+	cap      int
+	minCap   int
+	maxCap   int
+
+	adaptive   bool
+	rssCeiling uint64
+	rss        func() uint64
+}
+
+// newELFParseLimiter creates a limiter with the given starting capacity. The
+// capacity never grows past this value and never shrinks below 1.
+func newELFParseLimiter(capacity int, cfg DiscoveryConfig) *elfParseLimiter {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	l := &elfParseLimiter{
+		cap:        capacity,
+		minCap:     1,
+		maxCap:     capacity,
+		adaptive:   cfg.AdaptiveELFParseConcurrency,
+		rssCeiling: cfg.ELFParseRSSCeilingBytes,
+		rss:        currentRSSBytes,
+	}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// acquire blocks until a permit is available.
+func (l *elfParseLimiter) acquire() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.inFlight >= l.cap {
+		l.cond.Wait()
+	}
+	l.inFlight++
+}
+
+// release returns a permit, optionally adjusting the effective capacity in
+// adaptive mode. fast indicates whether the just-completed parse finished
+// quickly enough to be treated as a signal that the host has spare capacity.
+func (l *elfParseLimiter) release(fast bool) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+	if l.adaptive {
+		l.adjustLocked(fast)
+	}
+	l.cond.Broadcast()
+}
+
+// adjustLocked mutates cap within [minCap, maxCap]. Memory pressure always
+// takes priority over the "fast parse" growth signal.
+func (l *elfParseLimiter) adjustLocked(fast bool) {
+	if l.rssCeiling > 0 && l.rss != nil && l.rss() > l.rssCeiling {
+		if l.cap > l.minCap {
+			l.cap--
+		}
+		return
+	}
+	if fast && l.cap < l.maxCap {
+		l.cap++
+	}
+}
+
+// currentCap reports the limiter's current effective capacity. It exists
+// mainly so tests can observe adaptive shrink/grow behavior.
+func (l *elfParseLimiter) currentCap() int {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.cap
+}
+
+// defaultELFParseConcurrency returns a concurrency limit that scales with
+// the host: generous enough on large machines to parse several binaries at
+// once, but conservative on small ones (e.g. 2-core edge nodes) where Beyla
+// itself must stay a good neighbour.
+func defaultELFParseConcurrency() int {
+	if n := runtime.GOMAXPROCS(0) / 2; n > 2 {
+		return n
+	}
+	return 2
+}
+
+// currentRSSBytes returns the resident set size of the current process, or 0
+// if it could not be determined. It reads /proc/self/status, which is
+// available on every platform Beyla supports.
+func currentRSSBytes() uint64 {
+	data, err := os.ReadFile("/proc/self/status")
+	if err != nil {
+		return 0
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "VmRSS:") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return 0
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0
+		}
+		return kb * 1024
+	}
+	return 0
+}
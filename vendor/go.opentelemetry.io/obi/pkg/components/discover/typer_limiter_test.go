@@ -0,0 +1,113 @@
+package discover
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/obi/pkg/components/exec"
+	"go.opentelemetry.io/obi/pkg/internal/goexec"
+)
+
+func TestELFParseLimiterBoundsConcurrency(t *testing.T) {
+	limiter := newELFParseLimiter(3, DiscoveryConfig{})
+
+	var active, maxActive int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limiter.acquire()
+			defer limiter.release(false)
+
+			cur := atomic.AddInt32(&active, 1)
+			for {
+				m := atomic.LoadInt32(&maxActive)
+				if cur <= m || atomic.CompareAndSwapInt32(&maxActive, m, cur) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&active, -1)
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, maxActive, int32(3))
+}
+
+func TestELFParseLimiterNilIsUnbounded(t *testing.T) {
+	var limiter *elfParseLimiter
+	assert.NotPanics(t, func() {
+		limiter.acquire()
+		limiter.release(true)
+	})
+}
+
+func TestELFParseLimiterAdaptiveShrinksUnderMemoryPressure(t *testing.T) {
+	limiter := newELFParseLimiter(4, DiscoveryConfig{
+		AdaptiveELFParseConcurrency: true,
+		ELFParseRSSCeilingBytes:     1,
+	})
+	limiter.rss = func() uint64 { return 2 } // always "over the ceiling"
+
+	limiter.acquire()
+	limiter.release(false)
+	assert.Equal(t, 3, limiter.currentCap())
+
+	limiter.acquire()
+	limiter.release(false)
+	assert.Equal(t, 2, limiter.currentCap())
+}
+
+func TestELFParseLimiterAdaptiveGrowsBackWhenFast(t *testing.T) {
+	limiter := newELFParseLimiter(4, DiscoveryConfig{
+		AdaptiveELFParseConcurrency: true,
+		ELFParseRSSCeilingBytes:     100,
+	})
+	limiter.rss = func() uint64 { return 1 } // always under the ceiling
+	limiter.cap = 1                          // simulate a prior shrink
+
+	limiter.acquire()
+	limiter.release(true) // fast parse: grow back towards maxCap
+	assert.Equal(t, 2, limiter.currentCap())
+}
+
+func TestWithELFParseConcurrencyOption(t *testing.T) {
+	typer := newTyper(DiscoveryConfig{ELFParseConcurrency: 8}, nil, nil, WithELFParseConcurrency(2))
+
+	var active, maxActive int32
+	typer.inspectOffsets = func(_ *exec.FileInfo) (*goexec.Offsets, bool, error) {
+		cur := atomic.AddInt32(&active, 1)
+		for {
+			m := atomic.LoadInt32(&maxActive)
+			if cur <= m || atomic.CompareAndSwapInt32(&maxActive, m, cur) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+		return &goexec.Offsets{}, true, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func(ino uint64) {
+			defer wg.Done()
+			_ = typer.asInstrumentable(&exec.FileInfo{Pid: int32(ino), Ino: ino})
+		}(uint64(i + 1))
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, maxActive, int32(2),
+		"WithELFParseConcurrency should override the DiscoveryConfig default")
+}
+
+func TestDefaultELFParseConcurrencyIsAtLeastTwo(t *testing.T) {
+	assert.GreaterOrEqual(t, defaultELFParseConcurrency(), 2)
+}
@@ -15,23 +15,18 @@ import (
 	"go.opentelemetry.io/obi/pkg/internal/goexec"
 )
 
-// TestConcurrentELFParsing verifies that the semaphore correctly limits
+// TestConcurrentELFParsing verifies that the limiter correctly bounds
 // concurrent ELF parsing operations
 func TestConcurrentELFParsing(t *testing.T) {
-	// Save original semaphore and restore after test
-	originalSem := elfParseSem
-	defer func() { elfParseSem = originalSem }()
-	
-	// Create a semaphore with limit of 2
-	elfParseSem = make(chan struct{}, 2)
-	
-	// Create test typer with empty cache
+	// Create test typer with empty cache and a limiter capped at 2, without
+	// touching any package-level state.
 	cache, _ := lru.New[uint64, InstrumentedExecutable](10)
 	typer := &typer{
 		instrumentableCache: cache,
 		log:                 slog.Default(),
+		limiter:             newELFParseLimiter(2, DiscoveryConfig{}),
 	}
-	
+
 	// Track concurrent parsers
 	var activeParsers int32
 	var maxActiveParsers int32
@@ -0,0 +1,165 @@
+package discover
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"go.opentelemetry.io/obi/pkg/components/svc"
+)
+
+// Parse error causes, used as the "cause" label on the parseErrors counter.
+const (
+	parseErrorNotELF           = "not_elf"
+	parseErrorNoSymbols        = "no_symbols"
+	parseErrorUnsupportedGoVer = "unsupported_go_version"
+	parseErrorOther            = "other"
+)
+
+// typerMetrics groups the Prometheus instrumentation for the ELF-parsing
+// subsystem. A nil *typerMetrics is valid: every method is a no-op, so a
+// typer can be used without metrics configured (as most tests do).
+type typerMetrics struct {
+	cacheHits      prometheus.Counter
+	cacheMisses    prometheus.Counter
+	negativeHits   prometheus.Counter
+	diskCacheHits  prometheus.Counter
+	inFlightParses prometheus.Gauge
+	parseDuration  *prometheus.HistogramVec
+	parseErrors    *prometheus.CounterVec
+}
+
+// newTyperMetrics registers the ELF-parsing metrics with reg, Beyla's
+// existing internal metrics registry. Passing a nil registerer disables
+// metrics entirely.
+func newTyperMetrics(reg prometheus.Registerer) *typerMetrics {
+	if reg == nil {
+		return nil
+	}
+
+	m := &typerMetrics{
+		cacheHits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "beyla",
+			Subsystem: "discover",
+			Name:      "instrumentable_cache_hits_total",
+			Help:      "Number of asInstrumentable calls served from a cached, successfully typed result.",
+		}),
+		cacheMisses: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "beyla",
+			Subsystem: "discover",
+			Name:      "instrumentable_cache_misses_total",
+			Help:      "Number of asInstrumentable calls that required a fresh ELF parse.",
+		}),
+		negativeHits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "beyla",
+			Subsystem: "discover",
+			Name:      "instrumentable_cache_negative_hits_total",
+			Help:      "Number of asInstrumentable calls served from a cached failed or not-instrumentable result.",
+		}),
+		diskCacheHits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "beyla",
+			Subsystem: "discover",
+			Name:      "instrumentable_disk_cache_hits_total",
+			Help:      "Number of asInstrumentable calls served from the persistent on-disk cache.",
+		}),
+		inFlightParses: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "beyla",
+			Subsystem: "discover",
+			Name:      "elf_parses_in_flight",
+			Help:      "Number of ELF parses currently holding a permit from the typer's concurrency limiter.",
+		}),
+		parseDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "beyla",
+			Subsystem: "discover",
+			Name:      "elf_parse_duration_seconds",
+			Help:      "Duration of inspectOffsets calls, bucketed by the resulting instrumentable type.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"type"}),
+		parseErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "beyla",
+			Subsystem: "discover",
+			Name:      "elf_parse_errors_total",
+			Help:      "Number of ELF parse failures, broken down by cause.",
+		}, []string{"cause"}),
+	}
+
+	reg.MustRegister(m.cacheHits, m.cacheMisses, m.negativeHits, m.diskCacheHits, m.inFlightParses, m.parseDuration, m.parseErrors)
+
+	return m
+}
+
+// observeCacheLookup records a cache hit, distinguishing a cached failure
+// (negative) from a cached successfully typed executable.
+func (m *typerMetrics) observeCacheLookup(negative bool) {
+	if m == nil {
+		return
+	}
+	if negative {
+		m.negativeHits.Inc()
+		return
+	}
+	m.cacheHits.Inc()
+}
+
+// observeDiskCacheHit records an asInstrumentable call served from the
+// persistent on-disk cache, which otherwise bypasses both parseStarted and
+// observeCacheLookup and would be invisible to every other counter.
+func (m *typerMetrics) observeDiskCacheHit() {
+	if m == nil {
+		return
+	}
+	m.diskCacheHits.Inc()
+}
+
+func (m *typerMetrics) parseStarted() {
+	if m == nil {
+		return
+	}
+	m.cacheMisses.Inc()
+	m.inFlightParses.Inc()
+}
+
+func (m *typerMetrics) parseFinished() {
+	if m == nil {
+		return
+	}
+	m.inFlightParses.Dec()
+}
+
+func (m *typerMetrics) observeParseDuration(instrumentableType svc.InstrumentableType, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.parseDuration.WithLabelValues(fmt.Sprintf("%v", instrumentableType)).Observe(d.Seconds())
+}
+
+func (m *typerMetrics) observeParseError(cause string) {
+	if m == nil {
+		return
+	}
+	m.parseErrors.WithLabelValues(cause).Inc()
+}
+
+// classifyParseError maps an inspectOffsets error to a coarse cause label
+// suitable for the parseErrors counter. It matches on the error message
+// because the underlying ELF/DWARF parsing errors are plain errors, not a
+// typed error hierarchy.
+func classifyParseError(err error) string {
+	if err == nil {
+		return parseErrorOther
+	}
+
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "not an elf") || strings.Contains(msg, "bad elf"):
+		return parseErrorNotELF
+	case strings.Contains(msg, "no symbol") || strings.Contains(msg, "missing symbol table"):
+		return parseErrorNoSymbols
+	case strings.Contains(msg, "unsupported go version") || strings.Contains(msg, "go version too old"):
+		return parseErrorUnsupportedGoVer
+	default:
+		return parseErrorOther
+	}
+}
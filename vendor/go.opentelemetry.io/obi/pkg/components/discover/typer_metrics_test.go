@@ -0,0 +1,114 @@
+package discover
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/obi/pkg/components/exec"
+	"go.opentelemetry.io/obi/pkg/components/svc"
+	"go.opentelemetry.io/obi/pkg/internal/goexec"
+)
+
+func counterValue(t *testing.T, c prometheus.Counter) float64 {
+	t.Helper()
+	var m dto.Metric
+	require.NoError(t, c.Write(&m))
+	return m.GetCounter().GetValue()
+}
+
+func TestTyperMetricsTracksCacheHitsMissesAndNegatives(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	metrics := newTyperMetrics(reg)
+
+	cache, _ := lru.New[uint64, InstrumentedExecutable](10)
+	typer := &typer{
+		instrumentableCache: cache,
+		log:                 slog.Default(),
+		metrics:             metrics,
+	}
+
+	callCount := 0
+	typer.inspectOffsets = func(_ *exec.FileInfo) (*goexec.Offsets, bool, error) {
+		callCount++
+		if callCount == 1 {
+			return &goexec.Offsets{}, true, nil
+		}
+		return nil, false, errors.New("no symbols found")
+	}
+
+	// First call for inode 1 is a miss that succeeds.
+	_ = typer.asInstrumentable(&exec.FileInfo{Pid: 1, Ino: 1})
+	// Second call for the same inode is a cache hit.
+	_ = typer.asInstrumentable(&exec.FileInfo{Pid: 2, Ino: 1})
+
+	// First call for inode 2 is a miss that fails (negative result).
+	_ = typer.asInstrumentable(&exec.FileInfo{Pid: 3, Ino: 2})
+	// Second call for the same inode is a negative cache hit.
+	_ = typer.asInstrumentable(&exec.FileInfo{Pid: 4, Ino: 2})
+
+	assert.Equal(t, float64(2), counterValue(t, metrics.cacheMisses))
+	assert.Equal(t, float64(1), counterValue(t, metrics.cacheHits))
+	assert.Equal(t, float64(1), counterValue(t, metrics.negativeHits))
+	assert.Equal(t, float64(1), counterValue(t, metrics.parseErrors.WithLabelValues(parseErrorNoSymbols)))
+}
+
+func TestDiskCacheHitStampsFileInfoAndRecordsMetric(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	metrics := newTyperMetrics(reg)
+
+	binPath := filepath.Join(t.TempDir(), "binary")
+	require.NoError(t, os.WriteFile(binPath, []byte("not really an ELF, just needs to be readable"), 0o644))
+	buildID, err := elfBuildID(binPath)
+	require.NoError(t, err)
+
+	dc, err := newDiskCache(t.TempDir(), 0, 0, slog.Default())
+	require.NoError(t, err)
+	dc.store(buildID, InstrumentedExecutable{Type: svc.InstrumentableGolang, Offsets: &goexec.Offsets{}})
+	dc.close()
+
+	cache, _ := lru.New[uint64, InstrumentedExecutable](10)
+	typer := &typer{
+		instrumentableCache: cache,
+		log:                 slog.Default(),
+		metrics:             metrics,
+		disk:                dc,
+	}
+	typer.inspectOffsets = func(_ *exec.FileInfo) (*goexec.Offsets, bool, error) {
+		t.Fatal("inspectOffsets should not be called on a disk cache hit")
+		return nil, false, nil
+	}
+
+	execElf := &exec.FileInfo{Pid: 1, Ino: 1, CmdExePath: binPath}
+	got := typer.asInstrumentable(execElf)
+
+	assert.Same(t, execElf, got.FileInfo, "disk cache hits must stamp FileInfo like every other return path")
+	assert.Equal(t, float64(1), counterValue(t, metrics.diskCacheHits))
+	assert.Equal(t, float64(0), counterValue(t, metrics.cacheMisses), "a disk hit must not be counted as a fresh parse")
+}
+
+func TestClassifyParseError(t *testing.T) {
+	assert.Equal(t, parseErrorNotELF, classifyParseError(errors.New("not an ELF file")))
+	assert.Equal(t, parseErrorNoSymbols, classifyParseError(errors.New("missing symbol table")))
+	assert.Equal(t, parseErrorUnsupportedGoVer, classifyParseError(errors.New("unsupported go version 1.1")))
+	assert.Equal(t, parseErrorOther, classifyParseError(errors.New("permission denied")))
+}
+
+func TestNilTyperMetricsAreNoOps(t *testing.T) {
+	var metrics *typerMetrics
+	assert.NotPanics(t, func() {
+		metrics.observeCacheLookup(true)
+		metrics.observeCacheLookup(false)
+		metrics.parseStarted()
+		metrics.parseFinished()
+		metrics.observeParseError(parseErrorOther)
+	})
+}
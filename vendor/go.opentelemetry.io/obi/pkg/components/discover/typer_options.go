@@ -0,0 +1,80 @@
+package discover
+
+import (
+	"log/slog"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// instrumentableCacheSize is the number of typed executables kept in the
+// in-memory LRU cache.
+const instrumentableCacheSize = 1024
+
+// typerOption configures a typer constructed via newTyper.
+type typerOption func(*typer)
+
+// WithDiskCache enables the persistent, build-ID-keyed on-disk cache,
+// rooted at dir. maxAge and maxFiles configure its eviction policy; a zero
+// value disables the corresponding check.
+func WithDiskCache(dir string, maxAge time.Duration, maxFiles int) typerOption {
+	return func(t *typer) {
+		cache, err := newDiskCache(dir, maxAge, maxFiles, t.log)
+		if err != nil {
+			t.log.Warn("could not initialize disk cache, falling back to in-memory only", "dir", dir, "error", err)
+			return
+		}
+		t.disk = cache
+	}
+}
+
+// WithELFParseConcurrency overrides the number of ELF/DWARF parses that may
+// run concurrently, and becomes the ceiling adaptive mode grows back towards.
+// Values below 1 are clamped to 1.
+func WithELFParseConcurrency(n int) typerOption {
+	return func(t *typer) {
+		if n < 1 {
+			n = 1
+		}
+		t.limiter.mu.Lock()
+		t.limiter.cap = n
+		t.limiter.maxCap = n
+		t.limiter.mu.Unlock()
+	}
+}
+
+// newTyper creates a typer from cfg, wiring up its in-memory cache, ELF-parse
+// limiter and metrics, then applying any additional options. Callers are
+// still responsible for assigning inspectOffsets: the real ELF/DWARF
+// inspection routine is wired in by the discovery pipeline that owns the
+// typer's lifecycle, keeping this package's unit tests free to stub it.
+func newTyper(cfg DiscoveryConfig, log *slog.Logger, reg prometheus.Registerer, opts ...typerOption) *typer {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	cache, _ := lru.New[uint64, InstrumentedExecutable](instrumentableCacheSize)
+
+	capacity := cfg.ELFParseConcurrency
+	if capacity <= 0 {
+		capacity = defaultELFParseConcurrency()
+	}
+
+	t := &typer{
+		log:                 log,
+		instrumentableCache: cache,
+		limiter:             newELFParseLimiter(capacity, cfg),
+		metrics:             newTyperMetrics(reg),
+	}
+
+	if cfg.DiskCacheDir != "" {
+		WithDiskCache(cfg.DiskCacheDir, cfg.DiskCacheMaxAge, cfg.DiskCacheMaxFiles)(t)
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
@@ -0,0 +1,110 @@
+package discover
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/obi/pkg/components/exec"
+	"go.opentelemetry.io/obi/pkg/internal/goexec"
+)
+
+// TestSingleflightDeduplicatesParses verifies that concurrent calls to
+// asInstrumentable for the same binary (same inode) result in exactly one
+// call to inspectOffsets, with every caller receiving the shared result.
+func TestSingleflightDeduplicatesParses(t *testing.T) {
+	cache, _ := lru.New[uint64, InstrumentedExecutable](10)
+	typer := &typer{
+		instrumentableCache: cache,
+		log:                 slog.Default(),
+	}
+
+	var parseCount int32
+	typer.inspectOffsets = func(_ *exec.FileInfo) (*goexec.Offsets, bool, error) {
+		atomic.AddInt32(&parseCount, 1)
+		// Give other goroutines a chance to pile up behind the
+		// singleflight call before this one returns.
+		time.Sleep(50 * time.Millisecond)
+		return &goexec.Offsets{}, true, nil
+	}
+
+	const goroutines = 20
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(pid int32) {
+			defer wg.Done()
+			execElf := &exec.FileInfo{
+				Pid:        pid,
+				CmdExePath: "/test/binary",
+				Ino:        42, // same inode for every goroutine
+			}
+			_ = typer.asInstrumentable(execElf)
+		}(int32(i))
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&parseCount),
+		"expected inspectOffsets to run exactly once for concurrent callers sharing an inode")
+}
+
+// TestSingleflightCachesNegativeResults verifies that a failed parse is
+// cached just like a successful one, so repeated lookups of an
+// uninstrumentable binary don't keep re-parsing it.
+func TestSingleflightCachesNegativeResults(t *testing.T) {
+	cache, _ := lru.New[uint64, InstrumentedExecutable](10)
+	typer := &typer{
+		instrumentableCache: cache,
+		log:                 slog.Default(),
+	}
+
+	var parseCount int32
+	typer.inspectOffsets = func(_ *exec.FileInfo) (*goexec.Offsets, bool, error) {
+		atomic.AddInt32(&parseCount, 1)
+		return nil, false, nil
+	}
+
+	execElf := &exec.FileInfo{Pid: 1, CmdExePath: "/test/not-instrumentable", Ino: 7}
+
+	for i := 0; i < 5; i++ {
+		_ = typer.asInstrumentable(execElf)
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&parseCount),
+		"expected negative results to be cached after the first parse")
+}
+
+// TestSingleflightDifferentInodesParseIndependently verifies that binaries
+// with different inodes are not deduplicated against each other.
+func TestSingleflightDifferentInodesParseIndependently(t *testing.T) {
+	cache, _ := lru.New[uint64, InstrumentedExecutable](10)
+	typer := &typer{
+		instrumentableCache: cache,
+		log:                 slog.Default(),
+	}
+
+	var parseCount int32
+	typer.inspectOffsets = func(_ *exec.FileInfo) (*goexec.Offsets, bool, error) {
+		atomic.AddInt32(&parseCount, 1)
+		return &goexec.Offsets{}, true, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(ino uint64) {
+			defer wg.Done()
+			execElf := &exec.FileInfo{Pid: int32(ino), CmdExePath: "/test/binary", Ino: ino}
+			_ = typer.asInstrumentable(execElf)
+		}(uint64(i + 1))
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(5), atomic.LoadInt32(&parseCount),
+		"expected one parse per distinct inode")
+}